@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// logLevelFlag is the raw value of --log-level, empty if the flag wasn't
+// passed. Registered on flag.CommandLine directly (rather than threaded
+// through main's flag struct) so this file is a self-contained addition:
+// main.go's flag.Parse() picks it up without needing to change.
+var logLevelFlag string
+
+// The --log family of flags, backing QueryLogs/LogFilter: `eget --log
+// --since 7d --repo 'sharkdp/*' --action install`. logFlag gates the whole
+// mode; the rest only matter when it's set.
+var (
+	logFlag       bool
+	logSinceFlag  string
+	logUntilFlag  string
+	logRepoFlag   string
+	logPathFlag   string
+	logActionFlag string
+	logLimitFlag  int
+)
+
+func init() {
+	flag.StringVar(&logLevelFlag, "log-level", "",
+		"set the minimum log level (debug, info, warn, error), overriding EGET_LOG_LEVEL")
+
+	flag.BoolVar(&logFlag, "log", false,
+		"query the eget operation log instead of installing anything")
+	flag.StringVar(&logSinceFlag, "since", "",
+		"with --log, only show entries at or after this time (e.g. 7d, 24h, or an RFC3339 timestamp)")
+	flag.StringVar(&logUntilFlag, "until", "",
+		"with --log, only show entries at or before this time (same formats as --since)")
+	flag.StringVar(&logRepoFlag, "repo", "",
+		"with --log, only show entries whose repo matches this glob, e.g. 'sharkdp/*'")
+	flag.StringVar(&logPathFlag, "path", "",
+		"with --log, only show entries whose installed path matches this glob")
+	flag.StringVar(&logActionFlag, "action", "",
+		"with --log, only show entries with one of these comma-separated actions")
+	flag.IntVar(&logLimitFlag, "limit", 0,
+		"with --log, stop after this many matching entries")
+}
+
+// ApplyLogLevelFlag applies --log-level to the package-level Logger,
+// overriding EGET_LOG_LEVEL as documented on SetDefaultLevel. main should call this
+// once flag.Parse has run, before any logging happens. It's a no-op if
+// --log-level wasn't passed.
+func ApplyLogLevelFlag() error {
+	if logLevelFlag == "" {
+		return nil
+	}
+	level, err := ParseLevel(logLevelFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --log-level: %w", err)
+	}
+	SetDefaultLevel(level)
+	return nil
+}
+
+// LogFilterFromFlags builds a LogFilter from the --since/--until/--repo/
+// --path/--action/--limit flags, for main to pass to QueryLogs when --log
+// is set.
+func LogFilterFromFlags() (LogFilter, error) {
+	since, err := ParseSince(logSinceFlag)
+	if err != nil {
+		return LogFilter{}, err
+	}
+	until, err := ParseSince(logUntilFlag)
+	if err != nil {
+		return LogFilter{}, err
+	}
+
+	var actions []string
+	if logActionFlag != "" {
+		for _, a := range strings.Split(logActionFlag, ",") {
+			actions = append(actions, strings.TrimSpace(a))
+		}
+	}
+
+	return LogFilter{
+		Since:    since,
+		Until:    until,
+		RepoGlob: logRepoFlag,
+		PathGlob: logPathFlag,
+		ActionIn: actions,
+		Limit:    logLimitFlag,
+	}, nil
+}
+
+// RunLogCommand implements the `eget --log` family of flags: it queries
+// the log with the filter built from --since/--until/--repo/--path/
+// --action/--limit and prints every matching entry. main should call this
+// instead of its normal install flow whenever --log is set.
+func RunLogCommand() error {
+	filter, err := LogFilterFromFlags()
+	if err != nil {
+		return err
+	}
+
+	entries, err := QueryLogs(filter)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		fmt.Println(FormatLogEntry(entry))
+	}
+	return nil
+}
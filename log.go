@@ -1,28 +1,368 @@
 package main
 
 import (
+	"compress/gzip"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/zyedidia/eget/home"
 )
 
-// LogEntry represents a single log entry for a binary operation
+// Level is the severity of a log entry, ordered from most to least verbose.
+type Level int
+
+// Log levels, gated via EGET_LOG_LEVEL or --log-level. The default is InfoLevel.
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+// String returns the upper-case name of the level, as used in log output.
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParseLevel parses a level name (case-insensitive) such as "debug" or "WARN".
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DEBUG":
+		return DebugLevel, nil
+	case "INFO", "":
+		return InfoLevel, nil
+	case "WARN", "WARNING":
+		return WarnLevel, nil
+	case "ERROR":
+		return ErrorLevel, nil
+	default:
+		return InfoLevel, fmt.Errorf("unknown log level %q", s)
+	}
+}
+
+// Format selects how a Logger renders an entry before writing it out.
+type Format int
+
+const (
+	// TabFormat writes a single tab-delimited line, matching eget's historical
+	// on-disk format so existing scripts/parsers keep working.
+	TabFormat Format = iota
+	// JSONFormat writes one JSON object per line (JSON Lines), suitable for
+	// piping through jq.
+	JSONFormat
+	// ColorFormat writes a human-readable, ANSI-colored line, intended for
+	// interactive stderr output rather than the log file.
+	ColorFormat
+)
+
+// LogEntry represents a single log entry for a binary operation.
 type LogEntry struct {
 	Timestamp time.Time
+	Level     Level
 	Repo      string
 	Path      string
 	Action    string
+	Fields    map[string]interface{}
+}
+
+// jsonLogEntry is the on-disk JSON Lines representation of a LogEntry.
+type jsonLogEntry struct {
+	Timestamp string                 `json:"ts"`
+	Level     string                 `json:"level"`
+	Repo      string                 `json:"repo,omitempty"`
+	Path      string                 `json:"path,omitempty"`
+	Action    string                 `json:"action"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Logger writes structured log entries in a configurable format to a
+// configurable destination. LogOperation uses a package-level default
+// Logger so existing callers don't need to change.
+//
+// level and format are mutated at runtime by SetLevel/SetFormat (e.g. from
+// a --log-level flag racing concurrent logging from LogOperationBatch), so
+// they're guarded by mu rather than plain field writes.
+type Logger struct {
+	out io.Writer
+
+	mu     sync.Mutex
+	level  Level
+	format Format
+
+	fields map[string]interface{}
+}
+
+// NewLogger creates a Logger that writes entries at or above level to out,
+// rendered using format.
+func NewLogger(out io.Writer, level Level, format Format) *Logger {
+	return &Logger{out: out, level: level, format: format}
+}
+
+// With returns a copy of the Logger with key=val attached to every entry it
+// logs from then on, similar to slog's With.
+func (l *Logger) With(key string, val interface{}) *Logger {
+	l.mu.Lock()
+	level, format := l.level, l.format
+	l.mu.Unlock()
+
+	fields := make(map[string]interface{}, len(l.fields)+1)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	fields[key] = val
+	return &Logger{out: l.out, level: level, format: format, fields: fields}
+}
+
+// SetLevel changes the minimum level the Logger will emit. Safe to call
+// concurrently with Debug/Info/Warn/Error from other goroutines.
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	l.level = level
+	l.mu.Unlock()
+}
+
+// Level returns the Logger's current minimum emitted level. Safe to call
+// concurrently with SetLevel.
+func (l *Logger) Level() Level {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+// SetFormat changes the format the Logger renders entries in. Safe to call
+// concurrently with Debug/Info/Warn/Error from other goroutines.
+func (l *Logger) SetFormat(format Format) {
+	l.mu.Lock()
+	l.format = format
+	l.mu.Unlock()
+}
+
+// Debug logs msg at DebugLevel with the given alternating key/value pairs.
+func (l *Logger) Debug(msg string, kvs ...interface{}) error {
+	return l.log(DebugLevel, msg, kvs...)
+}
+
+// Info logs msg at InfoLevel with the given alternating key/value pairs.
+func (l *Logger) Info(msg string, kvs ...interface{}) error {
+	return l.log(InfoLevel, msg, kvs...)
+}
+
+// Warn logs msg at WarnLevel with the given alternating key/value pairs.
+func (l *Logger) Warn(msg string, kvs ...interface{}) error {
+	return l.log(WarnLevel, msg, kvs...)
+}
+
+// Error logs msg at ErrorLevel with the given alternating key/value pairs.
+func (l *Logger) Error(msg string, kvs ...interface{}) error {
+	return l.log(ErrorLevel, msg, kvs...)
+}
+
+// log merges l.fields with kvs, builds a LogEntry, and writes it if level
+// passes the Logger's configured threshold.
+func (l *Logger) log(level Level, msg string, kvs ...interface{}) error {
+	l.mu.Lock()
+	threshold := l.level
+	l.mu.Unlock()
+	if level < threshold {
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(l.fields)+len(kvs)/2)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	for i := 0; i+1 < len(kvs); i += 2 {
+		key, ok := kvs[i].(string)
+		if !ok {
+			key = fmt.Sprint(kvs[i])
+		}
+		fields[key] = kvs[i+1]
+	}
+
+	entry := LogEntry{
+		Timestamp: time.Now().UTC(),
+		Level:     level,
+		Action:    msg,
+		Fields:    fields,
+	}
+	if repo, ok := fields["repo"].(string); ok {
+		entry.Repo = repo
+	}
+	if path, ok := fields["path"].(string); ok {
+		entry.Path = path
+	}
+
+	line, err := l.render(entry)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(l.out, line)
+	return err
+}
+
+// render formats entry according to l.format.
+func (l *Logger) render(entry LogEntry) (string, error) {
+	l.mu.Lock()
+	format := l.format
+	l.mu.Unlock()
+
+	switch format {
+	case JSONFormat:
+		return renderJSON(entry)
+	case ColorFormat:
+		return renderColor(entry), nil
+	default:
+		return renderTab(entry), nil
+	}
+}
+
+// extraFields returns entry.Fields minus the keys already surfaced as named
+// LogEntry members, formatted as "key=val" pairs sorted by key for
+// deterministic output.
+func extraFields(fields map[string]interface{}, skip ...string) []string {
+	skipSet := make(map[string]bool, len(skip))
+	for _, k := range skip {
+		skipSet[k] = true
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if !skipSet[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, fields[k]))
+	}
+	return pairs
+}
+
+// renderTab renders entry in the legacy tab-delimited format:
+// timestamp\trepo\tpath\taction, with any extra fields appended as a final
+// tab-delimited key=val column so old parsers (which only read the first
+// four columns) keep working unchanged.
+func renderTab(entry LogEntry) string {
+	line := fmt.Sprintf("%s\t%s\t%s\t%s",
+		entry.Timestamp.Format(time.RFC3339), entry.Repo, entry.Path, entry.Action)
+	if extra := extraFields(entry.Fields, "repo", "path"); len(extra) > 0 {
+		line += "\t" + strings.Join(extra, " ")
+	}
+	return line + "\n"
+}
+
+// renderJSON renders entry as a single JSON Lines record.
+func renderJSON(entry LogEntry) (string, error) {
+	fields := make(map[string]interface{}, len(entry.Fields))
+	for k, v := range entry.Fields {
+		if k == "repo" || k == "path" {
+			continue
+		}
+		fields[k] = v
+	}
+
+	b, err := json.Marshal(jsonLogEntry{
+		Timestamp: entry.Timestamp.Format(time.RFC3339),
+		Level:     entry.Level.String(),
+		Repo:      entry.Repo,
+		Path:      entry.Path,
+		Action:    entry.Action,
+		Fields:    fields,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal log entry: %w", err)
+	}
+	return string(b) + "\n", nil
+}
+
+// levelColor maps each level to its ANSI color code, used by renderColor.
+var levelColor = map[Level]string{
+	DebugLevel: "\x1b[90m", // gray
+	InfoLevel:  "\x1b[36m", // cyan
+	WarnLevel:  "\x1b[33m", // yellow
+	ErrorLevel: "\x1b[31m", // red
+}
+
+const ansiReset = "\x1b[0m"
+
+// renderColor renders entry as a human-readable, color-coded line intended
+// for interactive stderr output.
+func renderColor(entry LogEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%-5s%s %s %s",
+		levelColor[entry.Level], entry.Level, ansiReset,
+		entry.Timestamp.Format(time.RFC3339), entry.Action)
+	if entry.Repo != "" {
+		fmt.Fprintf(&b, " repo=%s", entry.Repo)
+	}
+	if entry.Path != "" {
+		fmt.Fprintf(&b, " path=%s", entry.Path)
+	}
+	for _, kv := range extraFields(entry.Fields, "repo", "path") {
+		b.WriteByte(' ')
+		b.WriteString(kv)
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// defaultWriter is the package-level RotatingWriter used by defaultLogger.
+// Logs are gzip-compressed once rotated out and kept for 30 days by
+// default; MaxSizeMB guards against a single day's file growing unbounded.
+var defaultWriter = NewRotatingWriter("", true, 30, 100)
+
+// defaultLogger is the package-level Logger used by LogOperation. Its level
+// is controlled by EGET_LOG_LEVEL (and overridable via SetDefaultLevel, e.g.
+// from a --log-level flag).
+var defaultLogger = NewLogger(defaultWriter, levelFromEnv(), TabFormat)
+
+// levelFromEnv reads EGET_LOG_LEVEL, defaulting to InfoLevel if unset or
+// invalid.
+func levelFromEnv() Level {
+	level, err := ParseLevel(os.Getenv("EGET_LOG_LEVEL"))
+	if err != nil {
+		return InfoLevel
+	}
+	return level
+}
+
+// SetDefaultLevel overrides the level of the package-level Logger, e.g. from
+// a --log-level flag (which should take precedence over EGET_LOG_LEVEL).
+func SetDefaultLevel(level Level) {
+	defaultLogger.SetLevel(level)
+}
+
+// SetDefaultFormat overrides the format of the package-level Logger.
+func SetDefaultFormat(format Format) {
+	defaultLogger.SetFormat(format)
 }
 
 // GetLogDir returns the appropriate log directory based on the OS
 func GetLogDir() (string, error) {
 	var logDir string
-	
+
 	if runtime.GOOS == "windows" {
 		// Windows: use %LOCALAPPDATA%\eget\logs
 		localAppData := os.Getenv("LOCALAPPDATA")
@@ -42,112 +382,226 @@ func GetLogDir() (string, error) {
 		}
 		logDir = filepath.Join(homeDir, ".local", "share", "eget", "logs")
 	}
-	
+
 	return logDir, nil
 }
 
-// GetLogFilePath returns the full path to the log file
+// GetLogFilePath returns the full path to today's active log file. Older
+// entries live in separate rotated files alongside it; see ReadLogs.
 func GetLogFilePath() (string, error) {
 	logDir, err := GetLogDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(logDir, "eget.log"), nil
+	return filepath.Join(logDir, dailyLogName(time.Now())), nil
 }
 
-// ensureLogDir creates the log directory if it doesn't exist
-func ensureLogDir() error {
-	logDir, err := GetLogDir()
-	if err != nil {
-		return err
-	}
-	
-	return os.MkdirAll(logDir, 0755)
+// LogOperation logs a binary operation to the log file. It is a thin
+// wrapper over the package-level Logger, kept for backward compatibility.
+func LogOperation(repo, path, action string) error {
+	return defaultLogger.Info(action, "repo", repo, "path", path)
 }
 
-// LogOperation logs a binary operation to the log file
-func LogOperation(repo, path, action string) error {
-	// Ensure log directory exists
-	if err := ensureLogDir(); err != nil {
-		return fmt.Errorf("failed to create log directory: %w", err)
+// LogOperationBatch logs many entries under a single cross-process file
+// lock, instead of taking and releasing the lock once per entry. This is
+// meant for the batch-install subsystem, which otherwise logs one entry per
+// asset in quick succession.
+//
+// Entries below the Logger's configured level threshold are dropped, the
+// same as a Debug/Info/Warn/Error call would be by log().
+func LogOperationBatch(entries []LogEntry) error {
+	if len(entries) == 0 {
+		return nil
 	}
-	
-	logFile, err := GetLogFilePath()
-	if err != nil {
-		return fmt.Errorf("failed to get log file path: %w", err)
+
+	threshold := defaultLogger.Level()
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Level < threshold {
+			continue
+		}
+		if entry.Timestamp.IsZero() {
+			entry.Timestamp = time.Now().UTC()
+		}
+		line, err := defaultLogger.render(entry)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line)
 	}
-	
-	// Open file in append mode, create if doesn't exist
-	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
+	if len(lines) == 0 {
+		return nil
 	}
-	defer f.Close()
-	
-	// Format: timestamp\trepo\tpath\taction
-	timestamp := time.Now().UTC().Format(time.RFC3339)
-	logLine := fmt.Sprintf("%s\t%s\t%s\t%s\n", timestamp, repo, path, action)
-	
-	_, err = f.WriteString(logLine)
-	if err != nil {
-		return fmt.Errorf("failed to write to log file: %w", err)
+
+	if bw, ok := defaultLogger.out.(interface{ WriteBatch([]string) error }); ok {
+		return bw.WriteBatch(lines)
+	}
+	for _, line := range lines {
+		if _, err := io.WriteString(defaultLogger.out, line); err != nil {
+			return err
+		}
 	}
-	
 	return nil
 }
 
-// ReadLogs reads all log entries from the log file
+// ReadLogs reads all log entries across every eget-*.log and eget-*.log.gz
+// file in the log directory, oldest first, decompressing gzipped archives
+// on the fly. It transparently accepts both the legacy tab-delimited
+// format and the newer JSON Lines format, since either may appear
+// depending on which Format wrote a given file.
 func ReadLogs() ([]LogEntry, error) {
-	logFile, err := GetLogFilePath()
+	logDir, err := defaultWriter.dir()
 	if err != nil {
 		return nil, err
 	}
-	
-	data, err := os.ReadFile(logFile)
+
+	files, err := listLogFiles(logDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log files: %w", err)
+	}
+
+	entries := make([]LogEntry, 0, len(files))
+	for _, f := range files {
+		lines, err := readLogFileLines(filepath.Join(logDir, f.name), f.compressed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read log file %s: %w", f.name, err)
+		}
+		for _, line := range lines {
+			entry, ok := parseLogLine(line)
+			if !ok {
+				continue // skip malformed lines
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// readLogFileLines returns every non-blank line in path, transparently
+// gunzipping it first if compressed is set.
+func readLogFileLines(path string, compressed bool) ([]string, error) {
+	f, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return []LogEntry{}, nil
+			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to read log file: %w", err)
+		return nil, err
 	}
-	
-	lines := strings.Split(string(data), "\n")
-	entries := make([]LogEntry, 0, len(lines))
-	
-	for _, line := range lines {
+	defer f.Close()
+
+	var r io.Reader = f
+	if compressed {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		r = gr
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+		if line != "" {
+			lines = append(lines, line)
 		}
-		
-		parts := strings.Split(line, "\t")
-		if len(parts) != 4 {
-			continue // skip malformed lines
+	}
+	return lines, nil
+}
+
+// parseLogLine parses a single log line in either JSON Lines or legacy
+// tab-delimited format.
+func parseLogLine(line string) (LogEntry, bool) {
+	if strings.HasPrefix(line, "{") {
+		return parseJSONLine(line)
+	}
+	return parseTabLine(line)
+}
+
+func parseJSONLine(line string) (LogEntry, bool) {
+	var raw jsonLogEntry
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return LogEntry{}, false
+	}
+	ts, err := time.Parse(time.RFC3339, raw.Timestamp)
+	if err != nil {
+		return LogEntry{}, false
+	}
+	level, err := ParseLevel(raw.Level)
+	if err != nil {
+		level = InfoLevel
+	}
+	return LogEntry{
+		Timestamp: ts,
+		Level:     level,
+		Repo:      raw.Repo,
+		Path:      raw.Path,
+		Action:    raw.Action,
+		Fields:    raw.Fields,
+	}, true
+}
+
+func parseTabLine(line string) (LogEntry, bool) {
+	parts := strings.SplitN(line, "\t", 5)
+	if len(parts) < 4 {
+		return LogEntry{}, false
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, parts[0])
+	if err != nil {
+		return LogEntry{}, false
+	}
+
+	entry := LogEntry{
+		Timestamp: timestamp,
+		Level:     InfoLevel,
+		Repo:      parts[1],
+		Path:      parts[2],
+		Action:    parts[3],
+	}
+	if len(parts) == 5 {
+		entry.Fields = parseExtraFields(parts[4])
+	}
+	return entry, true
+}
+
+// parseExtraFields parses the space-separated "key=val" column appended by
+// renderTab back into a map.
+func parseExtraFields(s string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for _, kv := range strings.Fields(s) {
+		k, v, found := strings.Cut(kv, "=")
+		if !found {
+			continue
 		}
-		
-		timestamp, err := time.Parse(time.RFC3339, parts[0])
-		if err != nil {
-			continue // skip lines with invalid timestamps
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			fields[k] = n
+			continue
 		}
-		
-		entries = append(entries, LogEntry{
-			Timestamp: timestamp,
-			Repo:      parts[1],
-			Path:      parts[2],
-			Action:    parts[3],
-		})
+		fields[k] = v
 	}
-	
-	return entries, nil
+	return fields
 }
 
 // FormatLogEntry formats a log entry for display
 func FormatLogEntry(entry LogEntry) string {
-	return fmt.Sprintf("%s\t%s\t%s\t%s",
+	line := fmt.Sprintf("%s\t%-5s\t%s\t%s\t%s",
 		entry.Timestamp.Format(time.RFC3339),
+		entry.Level,
 		entry.Repo,
 		entry.Path,
 		entry.Action)
+	if extra := extraFields(entry.Fields, "repo", "path"); len(extra) > 0 {
+		line += "\t" + strings.Join(extra, " ")
+	}
+	return line
 }
 
 // PrintLogs prints all log entries
@@ -156,10 +610,10 @@ func PrintLogs() error {
 	if err != nil {
 		return err
 	}
-	
+
 	for _, entry := range entries {
 		fmt.Println(FormatLogEntry(entry))
 	}
-	
+
 	return nil
 }
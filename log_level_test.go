@@ -0,0 +1,177 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLevelString(t *testing.T) {
+	cases := []struct {
+		level Level
+		want  string
+	}{
+		{DebugLevel, "DEBUG"},
+		{InfoLevel, "INFO"},
+		{WarnLevel, "WARN"},
+		{ErrorLevel, "ERROR"},
+		{Level(99), "UNKNOWN"},
+	}
+	for _, c := range cases {
+		if got := c.level.String(); got != c.want {
+			t.Errorf("Level(%d).String() = %q, want %q", c.level, got, c.want)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    Level
+		wantErr bool
+	}{
+		{"debug", DebugLevel, false},
+		{"DEBUG", DebugLevel, false},
+		{"info", InfoLevel, false},
+		{"", InfoLevel, false},
+		{"  warn  ", WarnLevel, false},
+		{"warning", WarnLevel, false},
+		{"ERROR", ErrorLevel, false},
+		{"bogus", InfoLevel, true},
+	}
+	for _, c := range cases {
+		got, err := ParseLevel(c.in)
+		if (err != nil) != c.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", c.in, err, c.wantErr)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+// TestLoggerLevelGating checks that log() drops entries below the
+// configured threshold and keeps entries at or above it.
+func TestLoggerLevelGating(t *testing.T) {
+	cw := &countingWriter{}
+	l := NewLogger(cw, WarnLevel, TabFormat)
+
+	if err := l.Info("should be dropped"); err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if cw.writes != 0 {
+		t.Errorf("Info below threshold reached the writer (writes=%d)", cw.writes)
+	}
+
+	if err := l.Warn("should pass"); err != nil {
+		t.Fatalf("Warn: %v", err)
+	}
+	if cw.writes != 1 {
+		t.Errorf("Warn at threshold did not reach the writer (writes=%d)", cw.writes)
+	}
+
+	l.SetLevel(ErrorLevel)
+	if got := l.Level(); got != ErrorLevel {
+		t.Errorf("Level() = %v after SetLevel(ErrorLevel), want ErrorLevel", got)
+	}
+	if err := l.Warn("now below threshold"); err != nil {
+		t.Fatalf("Warn: %v", err)
+	}
+	if cw.writes != 1 {
+		t.Errorf("Warn below new threshold reached the writer (writes=%d)", cw.writes)
+	}
+}
+
+// TestLoggerWith checks that With attaches a field to every subsequent
+// entry without mutating the parent Logger.
+func TestLoggerWith(t *testing.T) {
+	var buf strings.Builder
+	l := NewLogger(&buf, InfoLevel, JSONFormat)
+	child := l.With("repo", "owner/repo")
+
+	if err := child.Info("installed"); err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"repo":"owner/repo"`) {
+		t.Errorf("With-attached field missing from output: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := l.Info("installed"); err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if strings.Contains(buf.String(), "owner/repo") {
+		t.Errorf("With mutated the parent Logger's fields: %s", buf.String())
+	}
+}
+
+// TestRenderTabRoundTrip checks that renderTab's output is parsed back into
+// an equivalent LogEntry by parseTabLine, including the extra-fields column.
+func TestRenderTabRoundTrip(t *testing.T) {
+	entry := LogEntry{
+		Timestamp: time.Date(2026, 7, 25, 12, 0, 0, 0, time.UTC),
+		Level:     InfoLevel,
+		Repo:      "owner/repo",
+		Path:      "/usr/local/bin/tool",
+		Action:    "install",
+		Fields:    map[string]interface{}{"bytes": float64(1024)},
+	}
+
+	line := renderTab(entry)
+	got, ok := parseTabLine(strings.TrimSuffix(line, "\n"))
+	if !ok {
+		t.Fatalf("parseTabLine failed to parse renderTab output: %q", line)
+	}
+	if !got.Timestamp.Equal(entry.Timestamp) || got.Repo != entry.Repo || got.Path != entry.Path || got.Action != entry.Action {
+		t.Errorf("round-tripped entry = %+v, want %+v", got, entry)
+	}
+	if got.Fields["bytes"] != 1024.0 {
+		t.Errorf("round-tripped extra field bytes = %v, want 1024", got.Fields["bytes"])
+	}
+}
+
+// TestRenderJSONRoundTrip checks that renderJSON's output is parsed back
+// into an equivalent LogEntry by parseJSONLine.
+func TestRenderJSONRoundTrip(t *testing.T) {
+	entry := LogEntry{
+		Timestamp: time.Date(2026, 7, 25, 12, 0, 0, 0, time.UTC),
+		Level:     ErrorLevel,
+		Repo:      "owner/repo",
+		Path:      "/usr/local/bin/tool",
+		Action:    "install-failed",
+		Fields:    map[string]interface{}{"status": "404"},
+	}
+
+	line, err := renderJSON(entry)
+	if err != nil {
+		t.Fatalf("renderJSON: %v", err)
+	}
+	if !strings.HasPrefix(line, "{") {
+		t.Fatalf("renderJSON output isn't a JSON object: %q", line)
+	}
+
+	got, ok := parseJSONLine(strings.TrimSuffix(line, "\n"))
+	if !ok {
+		t.Fatalf("parseJSONLine failed to parse renderJSON output: %q", line)
+	}
+	if !got.Timestamp.Equal(entry.Timestamp) || got.Level != entry.Level || got.Repo != entry.Repo ||
+		got.Path != entry.Path || got.Action != entry.Action || got.Fields["status"] != "404" {
+		t.Errorf("round-tripped entry = %+v, want %+v", got, entry)
+	}
+}
+
+// TestRenderColorContainsLevelAndAction sanity-checks the human-readable
+// format without trying to match exact ANSI byte sequences.
+func TestRenderColorContainsLevelAndAction(t *testing.T) {
+	entry := LogEntry{
+		Timestamp: time.Date(2026, 7, 25, 12, 0, 0, 0, time.UTC),
+		Level:     WarnLevel,
+		Repo:      "owner/repo",
+		Action:    "retry",
+	}
+	out := renderColor(entry)
+	if !strings.Contains(out, "WARN") || !strings.Contains(out, "retry") || !strings.Contains(out, "repo=owner/repo") {
+		t.Errorf("renderColor output missing expected fields: %q", out)
+	}
+}
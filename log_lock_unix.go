@@ -0,0 +1,28 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockOSFile takes a blocking, exclusive advisory lock on f via flock(2).
+// This guards eget-YYYY-MM-DD.log against interleaved writes when multiple
+// eget processes log concurrently.
+//
+// No fcntl(2) fallback is implemented: every non-Windows GOOS eget
+// currently ships for (linux, darwin, freebsd, openbsd, netbsd,
+// dragonfly) implements flock natively via syscall.Flock. If eget ever
+// adds a release target without native flock (e.g. solaris or aix), this
+// file needs an fcntl-based lockOSFile for that GOOS before cross-process
+// log locking can be trusted there - flag that in review rather than
+// assuming this still holds.
+func lockOSFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+// unlockOSFile releases the lock taken by lockOSFile.
+func unlockOSFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
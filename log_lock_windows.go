@@ -0,0 +1,54 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// lockOSFile/unlockOSFile call LockFileEx/UnlockFileEx directly via
+// syscall.NewLazyDLL rather than importing golang.org/x/sys/windows, so
+// cross-process log locking doesn't add a module dependency that isn't
+// already pinned in go.mod/go.sum.
+var (
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+// lockfileExclusiveLock is LOCKFILE_EXCLUSIVE_LOCK from the Windows SDK.
+const lockfileExclusiveLock = 0x00000002
+
+// lockOSFile takes a blocking, exclusive lock on f via LockFileEx. This is
+// the Windows equivalent of the flock(2) lock used on Unix, guarding
+// eget-YYYY-MM-DD.log against interleaved writes from concurrent eget
+// processes.
+func lockOSFile(f *os.File) error {
+	var overlapped syscall.Overlapped
+	r, _, err := procLockFileEx.Call(
+		f.Fd(),
+		uintptr(lockfileExclusiveLock),
+		0, 1, 0,
+		uintptr(unsafe.Pointer(&overlapped)),
+	)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// unlockOSFile releases the lock taken by lockOSFile.
+func unlockOSFile(f *os.File) error {
+	var overlapped syscall.Overlapped
+	r, _, err := procUnlockFileEx.Call(
+		f.Fd(),
+		0, 1, 0,
+		uintptr(unsafe.Pointer(&overlapped)),
+	)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
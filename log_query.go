@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogFilter narrows down the entries returned by QueryLogs. The zero value
+// matches every entry. It backs the `eget --log` family of flags, e.g.
+// `eget --log --since 7d --repo 'sharkdp/*' --action install`.
+type LogFilter struct {
+	Since    time.Time // entries at or after Since are included
+	Until    time.Time // entries at or before Until are included
+	RepoGlob string    // filepath.Match pattern against LogEntry.Repo
+	PathGlob string    // filepath.Match pattern against LogEntry.Path
+	ActionIn []string  // if non-empty, only these actions are included
+	Limit    int       // if > 0, stop once this many entries have matched
+}
+
+// ParseSince parses a --since/--until flag value. It accepts an RFC3339
+// timestamp, or a relative duration (interpreted as "ago") using Go's
+// duration syntax extended with a "d" (day) unit, e.g. "7d", "36h", "90m".
+func ParseSince(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := parseRelativeDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: want a duration like 7d/24h or an RFC3339 timestamp", s)
+}
+
+// parseRelativeDuration extends time.ParseDuration with a "d" (day) unit,
+// since that isn't one Go recognizes natively.
+func parseRelativeDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid day count %q", days)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// QueryLogs returns the log entries matching filter, streaming from the
+// rotated/gzipped log files (oldest first) instead of loading everything
+// into memory. It stops early once filter.Limit entries have matched, or
+// once entries start exceeding filter.Until.
+func QueryLogs(filter LogFilter) ([]LogEntry, error) {
+	logDir, err := defaultWriter.dir()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := listLogFiles(logDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log files: %w", err)
+	}
+
+	var entries []LogEntry
+	for _, f := range files {
+		// Files are whole calendar days, sorted oldest first: once a file
+		// starts after Until, every later file will too, so we're done.
+		if !filter.Until.IsZero() && f.date.After(filter.Until) {
+			break
+		}
+		// Skip entire files that end before Since without opening them.
+		if !filter.Since.IsZero() && f.date.AddDate(0, 0, 1).Before(filter.Since) {
+			continue
+		}
+
+		done, err := scanLogFile(filepath.Join(logDir, f.name), f.compressed, filter, &entries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read log file %s: %w", f.name, err)
+		}
+		if done {
+			break
+		}
+	}
+
+	return entries, nil
+}
+
+// scanLogFile streams path line by line, appending matching entries to
+// *entries. It reports done=true once filter.Limit is reached or an entry
+// past filter.Until is seen, so QueryLogs can stop opening further files.
+func scanLogFile(path string, compressed bool, filter LogFilter, entries *[]LogEntry) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if compressed {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return false, err
+		}
+		defer gr.Close()
+		r = gr
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		entry, ok := parseLogLine(line)
+		if !ok {
+			continue
+		}
+		if !filter.Until.IsZero() && entry.Timestamp.After(filter.Until) {
+			return true, scanner.Err()
+		}
+		if !matchesFilter(entry, filter) {
+			continue
+		}
+
+		*entries = append(*entries, entry)
+		if filter.Limit > 0 && len(*entries) >= filter.Limit {
+			return true, scanner.Err()
+		}
+	}
+	return false, scanner.Err()
+}
+
+// matchesFilter reports whether entry satisfies every criterion in filter
+// other than Until, which the streaming scan already enforces as an early
+// cutoff.
+func matchesFilter(entry LogEntry, filter LogFilter) bool {
+	if !filter.Since.IsZero() && entry.Timestamp.Before(filter.Since) {
+		return false
+	}
+	if filter.RepoGlob != "" {
+		if ok, err := filepath.Match(filter.RepoGlob, entry.Repo); err != nil || !ok {
+			return false
+		}
+	}
+	if filter.PathGlob != "" {
+		if ok, err := filepath.Match(filter.PathGlob, entry.Path); err != nil || !ok {
+			return false
+		}
+	}
+	if len(filter.ActionIn) > 0 {
+		matched := false
+		for _, action := range filter.ActionIn {
+			if entry.Action == action {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	if got, err := ParseSince(""); err != nil || !got.IsZero() {
+		t.Errorf("ParseSince(\"\") = %v, %v; want zero time, nil", got, err)
+	}
+
+	want, err := time.Parse(time.RFC3339, "2026-07-20T10:00:00Z")
+	if err != nil {
+		t.Fatalf("time.Parse: %v", err)
+	}
+	if got, err := ParseSince("2026-07-20T10:00:00Z"); err != nil || !got.Equal(want) {
+		t.Errorf("ParseSince(RFC3339) = %v, %v; want %v, nil", got, err, want)
+	}
+
+	before := time.Now()
+	got, err := ParseSince("7d")
+	if err != nil {
+		t.Fatalf("ParseSince(\"7d\"): %v", err)
+	}
+	wantApprox := before.Add(-7 * 24 * time.Hour)
+	if diff := got.Sub(wantApprox); diff < -time.Second || diff > time.Second {
+		t.Errorf("ParseSince(\"7d\") = %v, want ~%v", got, wantApprox)
+	}
+
+	if _, err := ParseSince("90m"); err != nil {
+		t.Errorf("ParseSince(\"90m\") unexpected error: %v", err)
+	}
+	if _, err := ParseSince("36h"); err != nil {
+		t.Errorf("ParseSince(\"36h\") unexpected error: %v", err)
+	}
+
+	if _, err := ParseSince("not-a-time"); err == nil {
+		t.Errorf("ParseSince(\"not-a-time\") = nil error, want error")
+	}
+	if _, err := ParseSince("xd"); err == nil {
+		t.Errorf("ParseSince(\"xd\") = nil error, want error")
+	}
+}
+
+// queryLogsFixture writes three days of log entries as plain tab-formatted
+// files into dir and points defaultWriter at dir, restoring it on cleanup.
+func queryLogsFixture(t *testing.T, dir string) {
+	t.Helper()
+
+	days := map[string][]LogEntry{
+		"2026-07-20.log": {
+			{Timestamp: mustParse(t, "2026-07-20T10:00:00Z"), Repo: "sharkdp/fd", Path: "/usr/local/bin/fd", Action: "install"},
+			{Timestamp: mustParse(t, "2026-07-20T11:00:00Z"), Repo: "foo/bar", Path: "/usr/local/bin/bar", Action: "update"},
+		},
+		"2026-07-22.log": {
+			{Timestamp: mustParse(t, "2026-07-22T09:00:00Z"), Repo: "sharkdp/bat", Path: "/usr/local/bin/bat", Action: "install"},
+			{Timestamp: mustParse(t, "2026-07-22T10:00:00Z"), Repo: "zoo/zaz", Path: "/usr/local/bin/zaz", Action: "remove"},
+		},
+		"2026-07-24.log": {
+			{Timestamp: mustParse(t, "2026-07-24T08:00:00Z"), Repo: "sharkdp/eza", Path: "/usr/local/bin/eza", Action: "install"},
+		},
+	}
+
+	for name, entries := range days {
+		var content string
+		for _, e := range entries {
+			content += renderTab(e)
+		}
+		if err := os.WriteFile(filepath.Join(dir, logFilePrefix+name), []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+	}
+
+	oldWriter := defaultWriter
+	defaultWriter = NewRotatingWriter(dir, false, 0, 0)
+	t.Cleanup(func() { defaultWriter = oldWriter })
+}
+
+func mustParse(t *testing.T, s string) time.Time {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("time.Parse(%q): %v", s, err)
+	}
+	return ts
+}
+
+func actions(entries []LogEntry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Repo + ":" + e.Action
+	}
+	return out
+}
+
+func TestQueryLogsSinceUntil(t *testing.T) {
+	dir := t.TempDir()
+	queryLogsFixture(t, dir)
+
+	entries, err := QueryLogs(LogFilter{
+		Since: mustParse(t, "2026-07-21T00:00:00Z"),
+		Until: mustParse(t, "2026-07-23T00:00:00Z"),
+	})
+	if err != nil {
+		t.Fatalf("QueryLogs: %v", err)
+	}
+	want := []string{"sharkdp/bat:install", "zoo/zaz:remove"}
+	got := actions(entries)
+	if len(got) != len(want) {
+		t.Fatalf("QueryLogs(Since/Until) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("QueryLogs(Since/Until)[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestQueryLogsRepoGlob(t *testing.T) {
+	dir := t.TempDir()
+	queryLogsFixture(t, dir)
+
+	entries, err := QueryLogs(LogFilter{RepoGlob: "sharkdp/*"})
+	if err != nil {
+		t.Fatalf("QueryLogs: %v", err)
+	}
+	want := []string{"sharkdp/fd:install", "sharkdp/bat:install", "sharkdp/eza:install"}
+	got := actions(entries)
+	if len(got) != len(want) {
+		t.Fatalf("QueryLogs(RepoGlob) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("QueryLogs(RepoGlob)[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestQueryLogsPathGlob(t *testing.T) {
+	dir := t.TempDir()
+	queryLogsFixture(t, dir)
+
+	entries, err := QueryLogs(LogFilter{PathGlob: "/usr/local/bin/bat"})
+	if err != nil {
+		t.Fatalf("QueryLogs: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Repo != "sharkdp/bat" {
+		t.Errorf("QueryLogs(PathGlob) = %v, want a single sharkdp/bat entry", actions(entries))
+	}
+}
+
+func TestQueryLogsActionIn(t *testing.T) {
+	dir := t.TempDir()
+	queryLogsFixture(t, dir)
+
+	entries, err := QueryLogs(LogFilter{ActionIn: []string{"remove"}})
+	if err != nil {
+		t.Fatalf("QueryLogs: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Action != "remove" {
+		t.Errorf("QueryLogs(ActionIn) = %v, want a single remove entry", actions(entries))
+	}
+}
+
+func TestQueryLogsLimit(t *testing.T) {
+	dir := t.TempDir()
+	queryLogsFixture(t, dir)
+
+	entries, err := QueryLogs(LogFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("QueryLogs: %v", err)
+	}
+	want := []string{"sharkdp/fd:install", "foo/bar:update"}
+	got := actions(entries)
+	if len(got) != len(want) {
+		t.Fatalf("QueryLogs(Limit) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("QueryLogs(Limit)[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,527 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logFilePrefix is the common filename prefix for all rotated eget log
+// files, e.g. eget-2026-07-25.log or eget-2026-07-25.log.gz.
+const logFilePrefix = "eget-"
+
+// RotatingWriter is an io.Writer that appends to a day-stamped eget log
+// file (eget-YYYY-MM-DD.log), rotating to a new file whenever the date
+// changes or (secondarily) whenever MaxSizeMB is exceeded. It is the
+// default destination for the package-level Logger.
+type RotatingWriter struct {
+	// Dir is the directory holding log files. If empty, it is resolved via
+	// GetLogDir on first use.
+	Dir string
+	// Compress gzips rotated-out files in the background.
+	Compress bool
+	// ReserveDay is the maximum retention in days; archived files older
+	// than this are deleted by the janitor. Zero means keep forever.
+	ReserveDay int
+	// MaxSizeMB rotates the active file once it exceeds this size, in
+	// addition to the normal day-based rotation. Zero disables it.
+	MaxSizeMB int
+
+	mu       sync.Mutex
+	file     *os.File
+	filename string // basename of the currently open file
+	size     int64
+	seq      int
+
+	janitorOnce sync.Once
+}
+
+// NewRotatingWriter creates a RotatingWriter rooted at dir (resolved via
+// GetLogDir if empty).
+func NewRotatingWriter(dir string, compress bool, reserveDay, maxSizeMB int) *RotatingWriter {
+	return &RotatingWriter{Dir: dir, Compress: compress, ReserveDay: reserveDay, MaxSizeMB: maxSizeMB}
+}
+
+// dir returns the directory to write log files to, resolving it lazily so
+// RotatingWriter can be constructed before GetLogDir is safe to call.
+func (w *RotatingWriter) dir() (string, error) {
+	if w.Dir != "" {
+		return w.Dir, nil
+	}
+	return GetLogDir()
+}
+
+// Write appends p to the current day's log file, rotating first if the
+// date has rolled over or the active file has grown past MaxSizeMB.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.ensureOpenLocked(); err != nil {
+		return 0, err
+	}
+
+	n, err := w.writeLockedLocked(p)
+	w.size += int64(n)
+	if err == nil && w.MaxSizeMB > 0 && w.size >= int64(w.MaxSizeMB)*1024*1024 {
+		if rerr := w.rotateForSizeLocked(); rerr != nil {
+			fmt.Fprintf(os.Stderr, "eget: log rotation failed: %v\n", rerr)
+		}
+	}
+	return n, err
+}
+
+// WriteBatch writes every line in a single cross-process lock/unlock cycle,
+// for callers (e.g. a batch install) that need to log many entries at once
+// without paying the lock overhead per line.
+func (w *RotatingWriter) WriteBatch(lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.ensureOpenLocked(); err != nil {
+		return err
+	}
+
+	n, err := w.writeLockedLocked([]byte(strings.Join(lines, "")))
+	w.size += int64(n)
+	if err == nil && w.MaxSizeMB > 0 && w.size >= int64(w.MaxSizeMB)*1024*1024 {
+		if rerr := w.rotateForSizeLocked(); rerr != nil {
+			fmt.Fprintf(os.Stderr, "eget: log rotation failed: %v\n", rerr)
+		}
+	}
+	return err
+}
+
+// writeLockedLocked takes the cross-process file lock, writes p in a
+// single Write call, and releases the lock, so concurrent eget processes
+// never interleave partial lines. Callers must hold w.mu and have an open
+// w.file.
+//
+// Taking the lock first, before checking whether w.file has been archived
+// out from under us, matters: archiveLockedFile/compressLogFile hold the
+// very same per-file lock for their whole read-compress-remove sequence,
+// so once we acquire it here we know no compress is concurrently reading
+// or has already removed the path. reopenIfArchivedLocked then catches the
+// case where one finished (renamed/removed the file) while we were
+// waiting, and gets us writing to a live file instead of an unlinked one.
+func (w *RotatingWriter) writeLockedLocked(p []byte) (int, error) {
+	if err := lockOSFile(w.file); err != nil {
+		return 0, fmt.Errorf("failed to lock log file: %w", err)
+	}
+
+	if err := w.reopenIfArchivedLocked(); err != nil {
+		unlockOSFile(w.file)
+		return 0, err
+	}
+
+	n, err := w.file.Write(p)
+	if uerr := unlockOSFile(w.file); uerr != nil && err == nil {
+		err = uerr
+	}
+	return n, err
+}
+
+// reopenIfArchivedLocked detects whether w.file has been renamed or
+// removed out from under us since we opened it - e.g. another eget
+// process rotated/compressed it while we were holding a stale handle -
+// and if so, reopens a fresh file at the same path before the pending
+// write proceeds. Without this, a write would silently succeed against
+// the old, now-unlinked inode and be lost forever once the fd closes,
+// even though lockOSFile was held throughout.
+//
+// Callers must hold w.mu and have already taken the flock on w.file.
+func (w *RotatingWriter) reopenIfArchivedLocked() error {
+	dir, err := w.dir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, w.filename)
+
+	if pathInfo, err := os.Stat(path); err == nil {
+		if fileInfo, err := w.file.Stat(); err == nil && os.SameFile(pathInfo, fileInfo) {
+			return nil
+		}
+	}
+
+	unlockOSFile(w.file)
+	w.file.Close()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen log file after rotation: %w", err)
+	}
+	if err := lockOSFile(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to lock reopened log file: %w", err)
+	}
+
+	w.file = f
+	w.size = 0
+	if info, err := f.Stat(); err == nil {
+		w.size = info.Size()
+	}
+	return nil
+}
+
+// ensureOpenLocked makes sure the file for today is open, rotating the
+// previous day's file out first if necessary. Callers must hold w.mu.
+func (w *RotatingWriter) ensureOpenLocked() error {
+	dir, err := w.dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	name := dailyLogName(time.Now())
+	if w.file != nil && w.filename == name {
+		return nil
+	}
+	if w.file != nil {
+		w.closeAndArchiveLocked(dir)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	w.file = f
+	w.filename = name
+	w.size = 0
+	w.seq = 0
+	if info, err := f.Stat(); err == nil {
+		w.size = info.Size()
+	}
+
+	w.startJanitor()
+	return nil
+}
+
+// closeAndArchiveLocked hands the current file off to be compressed in the
+// background if configured to (it keeps its name, since the date has
+// already rolled over). Callers must hold w.mu.
+//
+// Like rotateForSizeLocked, it takes f's flock before handing it to
+// archiveLockedFile, so a concurrent process's lockOSFile+write blocks
+// until the read-compress-remove sequence is done instead of racing it.
+func (w *RotatingWriter) closeAndArchiveLocked(dir string) {
+	path := filepath.Join(dir, w.filename)
+	f := w.file
+	if !w.Compress {
+		f.Close()
+		return
+	}
+	if err := lockOSFile(f); err != nil {
+		f.Close()
+		return
+	}
+	go archiveLockedFile(f, path)
+}
+
+// rotateForSizeLocked archives the active file under a sequence-numbered
+// name (since it's not a new day yet) so the next Write reopens a fresh
+// file for today, then compresses the archive in the background if
+// configured to. Callers must hold w.mu.
+//
+// The archive happens while holding w.file's flock so it can't land
+// between another process's lockOSFile and its write: they'll block on
+// the lock, then reopenIfArchivedLocked will notice the path no longer
+// points at the file they had open and switch them onto the fresh one
+// instead.
+func (w *RotatingWriter) rotateForSizeLocked() error {
+	dir, err := w.dir()
+	if err != nil {
+		return err
+	}
+
+	oldPath := filepath.Join(dir, w.filename)
+
+	f := w.file
+	w.file = nil
+
+	if err := lockOSFile(f); err != nil {
+		return fmt.Errorf("failed to lock log file for rotation: %w", err)
+	}
+
+	newPath, seq, err := linkToNextSizeRotatedNameLocked(dir, oldPath, time.Now(), w.seq)
+	if err != nil {
+		unlockOSFile(f)
+		f.Close()
+		if os.IsNotExist(err) {
+			// Another process already rotated oldPath out (and possibly
+			// removed it) while we were waiting for the lock; there's
+			// nothing left for us to archive.
+			return nil
+		}
+		return fmt.Errorf("failed to archive log file: %w", err)
+	}
+	w.seq = seq
+	if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
+		unlockOSFile(f)
+		f.Close()
+		return fmt.Errorf("failed to archive log file: %w", err)
+	}
+
+	if !w.Compress {
+		unlockOSFile(f)
+		f.Close()
+		return nil
+	}
+	go archiveLockedFile(f, newPath)
+	return nil
+}
+
+// startJanitor launches the background cleanup goroutine exactly once per
+// RotatingWriter.
+func (w *RotatingWriter) startJanitor() {
+	w.janitorOnce.Do(func() {
+		go w.janitorLoop()
+	})
+}
+
+// janitorLoop runs the janitor immediately and then once a day for the
+// life of the process.
+func (w *RotatingWriter) janitorLoop() {
+	w.runJanitor()
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+	for range ticker.C {
+		w.runJanitor()
+	}
+}
+
+// runJanitor scans the log directory, deletes archives older than
+// ReserveDay, and compresses any uncompressed rotated-out files. It never
+// touches the currently active file.
+func (w *RotatingWriter) runJanitor() {
+	dir, err := w.dir()
+	if err != nil {
+		return
+	}
+
+	files, err := listLogFiles(dir)
+	if err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	active := w.filename
+	w.mu.Unlock()
+
+	now := time.Now()
+	for _, f := range files {
+		if f.name == active {
+			continue
+		}
+		if w.ReserveDay > 0 && now.Sub(f.date) > time.Duration(w.ReserveDay)*24*time.Hour {
+			removeArchivedFile(filepath.Join(dir, f.name))
+			continue
+		}
+		if w.Compress && !f.compressed {
+			compressLogFile(filepath.Join(dir, f.name))
+		}
+	}
+}
+
+// dailyLogName returns the active log filename for the given day.
+func dailyLogName(t time.Time) string {
+	return fmt.Sprintf("%s%s.log", logFilePrefix, t.Format("2006-01-02"))
+}
+
+// sizeRotatedLogName returns the archive filename used when a day's log is
+// rotated out mid-day because it exceeded MaxSizeMB.
+func sizeRotatedLogName(t time.Time, seq int) string {
+	return fmt.Sprintf("%s%s.%d.log", logFilePrefix, t.Format("2006-01-02"), seq)
+}
+
+// linkToNextSizeRotatedNameLocked hard-links oldPath to the first
+// eget-YYYY-MM-DD.N.log name not already taken, starting the search at
+// startSeq+1, and returns the path and sequence number it claimed.
+//
+// It uses os.Link rather than os.Rename because Link fails with
+// os.ErrExist if the destination already exists, instead of silently
+// overwriting it: two eget processes racing to rotate the same day's log
+// each keep their own w.seq counter, so without this they can compute the
+// identical archive name and clobber one another's data. Trying
+// successive names until one succeeds makes the claim atomic across
+// processes; the caller removes oldPath once the link is in place.
+func linkToNextSizeRotatedNameLocked(dir, oldPath string, t time.Time, startSeq int) (string, int, error) {
+	for seq := startSeq + 1; ; seq++ {
+		newPath := filepath.Join(dir, sizeRotatedLogName(t, seq))
+		err := os.Link(oldPath, newPath)
+		if err == nil {
+			return newPath, seq, nil
+		}
+		if !os.IsExist(err) {
+			return "", 0, err
+		}
+	}
+}
+
+// logFileInfo describes one rotated (or active) log file on disk.
+type logFileInfo struct {
+	name       string
+	date       time.Time
+	compressed bool
+}
+
+// listLogFiles returns every eget-*.log and eget-*.log.gz file in dir,
+// sorted chronologically (oldest first).
+func listLogFiles(dir string) ([]logFileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []logFileInfo
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		date, compressed, ok := parseLogFileName(e.Name())
+		if !ok {
+			continue
+		}
+		files = append(files, logFileInfo{name: e.Name(), date: date, compressed: compressed})
+	}
+
+	// Within one day, size-rotated archives (eget-D.N.log) come first in
+	// order of N, then the day's unnumbered file, which is the newest.
+	seq := func(name string) int {
+		var n int
+		rest := strings.TrimPrefix(name, logFilePrefix)[len("2006-01-02"):]
+		if _, err := fmt.Sscanf(rest, ".%d.", &n); err != nil {
+			return int(^uint(0) >> 1)
+		}
+		return n
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].date.Equal(files[j].date) {
+			return files[i].date.Before(files[j].date)
+		}
+		if si, sj := seq(files[i].name), seq(files[j].name); si != sj {
+			return si < sj
+		}
+		return files[i].name < files[j].name
+	})
+	return files, nil
+}
+
+// parseLogFileName extracts the encoded date from a rotated log filename
+// such as eget-2026-07-25.log, eget-2026-07-25.log.gz, or the
+// size-rotated eget-2026-07-25.1.log(.gz). The date is returned as local
+// midnight, since dailyLogName names files by the local date.
+func parseLogFileName(name string) (time.Time, bool, bool) {
+	if !strings.HasPrefix(name, logFilePrefix) || !strings.Contains(name, ".log") {
+		return time.Time{}, false, false
+	}
+	rest := strings.TrimPrefix(name, logFilePrefix)
+	if len(rest) < len("2006-01-02") {
+		return time.Time{}, false, false
+	}
+	date, err := time.ParseInLocation("2006-01-02", rest[:len("2006-01-02")], time.Local)
+	if err != nil {
+		return time.Time{}, false, false
+	}
+	return date, strings.HasSuffix(name, ".gz"), true
+}
+
+// compressLogFile opens path, takes the same per-file lock writers take
+// before each write, and gzips+removes it. Used by the janitor to compress
+// rotated-out files it finds lying around uncompressed (e.g. after a
+// process restart), so it's meant to run synchronously or in its own
+// goroutine; failures are reported to stderr rather than returned.
+func compressLogFile(path string) {
+	f, err := os.OpenFile(path, os.O_RDONLY, 0644)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "eget: failed to compress log file %s: %v\n", path, err)
+		}
+		return
+	}
+	if err := lockOSFile(f); err != nil {
+		f.Close()
+		fmt.Fprintf(os.Stderr, "eget: failed to compress log file %s: %v\n", path, err)
+		return
+	}
+	archiveLockedFile(f, path)
+}
+
+// archiveLockedFile gzips the already-open, already-locked f to path+".gz",
+// removes path, and releases the lock. Holding the lock for the whole
+// read-compress-remove sequence is what makes rotation safe against a
+// concurrent eget process that still has path open: its writeLockedLocked
+// blocks on the same lock until this returns, then reopenIfArchivedLocked
+// notices path no longer refers to its old handle and switches it onto a
+// fresh file instead of silently writing into the now-unlinked inode.
+//
+// Meant to run in its own goroutine (or synchronously from the janitor),
+// so failures are reported to stderr rather than returned.
+func archiveLockedFile(f *os.File, path string) {
+	defer f.Close()
+	defer unlockOSFile(f)
+
+	if err := gzipLockedFile(f, path); err != nil {
+		fmt.Fprintf(os.Stderr, "eget: failed to compress log file %s: %v\n", path, err)
+	}
+}
+
+// gzipLockedFile gzips the already-open f (read from the start) to
+// path+".gz" and removes path. The caller is expected to already hold the
+// lock on f for the duration.
+func gzipLockedFile(f *os.File, path string) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gw := gzip.NewWriter(out)
+	if _, err := io.Copy(gw, f); err != nil {
+		gw.Close()
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// removeArchivedFile deletes path, taking the same per-file lock first so
+// a straggling writer with a stale, already-open handle to the same inode
+// reopens a fresh file (via reopenIfArchivedLocked) instead of silently
+// appending to bytes that are about to be reclaimed.
+func removeArchivedFile(path string) {
+	f, err := os.OpenFile(path, os.O_RDONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	if err := lockOSFile(f); err != nil {
+		return
+	}
+	defer unlockOSFile(f)
+
+	os.Remove(path)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseLogFileName(t *testing.T) {
+	cases := []struct {
+		name           string
+		wantOK         bool
+		wantDate       string
+		wantCompressed bool
+	}{
+		{"eget-2026-07-25.log", true, "2026-07-25", false},
+		{"eget-2026-07-25.log.gz", true, "2026-07-25", true},
+		{"eget-2026-07-25.1.log", true, "2026-07-25", false},
+		{"eget-2026-07-25.3.log.gz", true, "2026-07-25", true},
+		{"not-a-log-file.txt", false, "", false},
+		{"eget-bogus-date.log", false, "", false},
+	}
+	for _, c := range cases {
+		date, compressed, ok := parseLogFileName(c.name)
+		if ok != c.wantOK {
+			t.Errorf("parseLogFileName(%q) ok = %v, want %v", c.name, ok, c.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if date.Format("2006-01-02") != c.wantDate {
+			t.Errorf("parseLogFileName(%q) date = %v, want %v", c.name, date.Format("2006-01-02"), c.wantDate)
+		}
+		if compressed != c.wantCompressed {
+			t.Errorf("parseLogFileName(%q) compressed = %v, want %v", c.name, compressed, c.wantCompressed)
+		}
+	}
+}
+
+// TestListLogFilesOrdering checks that listLogFiles returns every
+// recognized log file, oldest first, and silently skips unrelated files.
+func TestListLogFilesOrdering(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"eget-2026-07-25.log",
+		"eget-2026-07-23.log.gz",
+		"eget-2026-07-24.1.log",
+		"not-a-log.txt",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+	}
+
+	files, err := listLogFiles(dir)
+	if err != nil {
+		t.Fatalf("listLogFiles: %v", err)
+	}
+
+	var got []string
+	for _, f := range files {
+		got = append(got, f.name)
+	}
+	want := []string{"eget-2026-07-23.log.gz", "eget-2026-07-24.1.log", "eget-2026-07-25.log"}
+	if len(got) != len(want) {
+		t.Fatalf("listLogFiles returned %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("listLogFiles()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+// TestEnsureOpenLockedRotatesOnDayChange checks that opening today's file
+// while a previous day's file is still held archives the old one out
+// (closing it, since Compress is off) and leaves its content untouched.
+func TestEnsureOpenLockedRotatesOnDayChange(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingWriter(dir, false, 0, 0)
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	yesterdayName := dailyLogName(yesterday)
+	f, err := os.OpenFile(filepath.Join(dir, yesterdayName), os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	if _, err := f.WriteString("stale entry\n"); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+
+	w.mu.Lock()
+	w.file = f
+	w.filename = yesterdayName
+	w.mu.Unlock()
+
+	if _, err := w.Write([]byte("fresh entry\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	yesterdayContent, err := os.ReadFile(filepath.Join(dir, yesterdayName))
+	if err != nil {
+		t.Fatalf("ReadFile(yesterday): %v", err)
+	}
+	if string(yesterdayContent) != "stale entry\n" {
+		t.Errorf("yesterday's file content = %q, want %q", yesterdayContent, "stale entry\n")
+	}
+
+	todayContent, err := os.ReadFile(filepath.Join(dir, dailyLogName(time.Now())))
+	if err != nil {
+		t.Fatalf("ReadFile(today): %v", err)
+	}
+	if string(todayContent) != "fresh entry\n" {
+		t.Errorf("today's file content = %q, want %q", todayContent, "fresh entry\n")
+	}
+}
+
+// TestSizeRotation checks that exceeding MaxSizeMB archives the oversized
+// file under a sequence-numbered name and starts a fresh active file,
+// without losing any bytes from either.
+func TestSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingWriter(dir, false, 0, 1)
+
+	big := make([]byte, 1100*1024)
+	for i := range big {
+		big[i] = 'a'
+	}
+	if _, err := w.Write(big); err != nil {
+		t.Fatalf("Write(big): %v", err)
+	}
+	if _, err := w.Write([]byte("small\n")); err != nil {
+		t.Fatalf("Write(small): %v", err)
+	}
+
+	archived, err := os.ReadFile(filepath.Join(dir, sizeRotatedLogName(time.Now(), 1)))
+	if err != nil {
+		t.Fatalf("ReadFile(archived): %v", err)
+	}
+	if len(archived) != len(big) {
+		t.Errorf("archived file has %d bytes, want %d", len(archived), len(big))
+	}
+
+	active, err := os.ReadFile(filepath.Join(dir, dailyLogName(time.Now())))
+	if err != nil {
+		t.Fatalf("ReadFile(active): %v", err)
+	}
+	if string(active) != "small\n" {
+		t.Errorf("active file content = %q, want %q", active, "small\n")
+	}
+}
+
+// TestJanitorDeletesExpiredArchives checks that runJanitor removes archived
+// files older than ReserveDay while leaving recent ones and the active
+// file alone.
+func TestJanitorDeletesExpiredArchives(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingWriter(dir, false, 7, 0)
+
+	old := dailyLogName(time.Now().Add(-30 * 24 * time.Hour))
+	recent := dailyLogName(time.Now().Add(-2 * 24 * time.Hour))
+	active := dailyLogName(time.Now())
+
+	for _, name := range []string{old, recent, active} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+	}
+
+	w.mu.Lock()
+	w.filename = active
+	w.mu.Unlock()
+
+	w.runJanitor()
+
+	if _, err := os.Stat(filepath.Join(dir, old)); !os.IsNotExist(err) {
+		t.Errorf("expired archive %s still exists (err=%v)", old, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, recent)); err != nil {
+		t.Errorf("recent archive %s was removed: %v", recent, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, active)); err != nil {
+		t.Errorf("active file %s was removed: %v", active, err)
+	}
+}
+
+// TestJanitorCompressesUncompressedArchives checks that runJanitor gzips
+// rotated-out files it finds lying around uncompressed (e.g. after a
+// process restart) when Compress is set, leaving the active file alone.
+func TestJanitorCompressesUncompressedArchives(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingWriter(dir, true, 0, 0)
+
+	archived := dailyLogName(time.Now().Add(-24 * time.Hour))
+	active := dailyLogName(time.Now())
+
+	if err := os.WriteFile(filepath.Join(dir, archived), []byte("archived content\n"), 0644); err != nil {
+		t.Fatalf("WriteFile(archived): %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, active), []byte("active content\n"), 0644); err != nil {
+		t.Fatalf("WriteFile(active): %v", err)
+	}
+
+	w.mu.Lock()
+	w.filename = active
+	w.mu.Unlock()
+
+	w.runJanitor()
+
+	if _, err := os.Stat(filepath.Join(dir, archived)); !os.IsNotExist(err) {
+		t.Errorf("uncompressed archive %s still exists after janitor, err=%v", archived, err)
+	}
+	gz, err := os.Open(filepath.Join(dir, archived+".gz"))
+	if err != nil {
+		t.Fatalf("janitor did not produce %s.gz: %v", archived, err)
+	}
+	defer gz.Close()
+
+	gr, err := gzip.NewReader(gz)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	defer gr.Close()
+	content, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("reading gzipped archive: %v", err)
+	}
+	if string(content) != "archived content\n" {
+		t.Errorf("gzipped archive content = %q, want %q", content, "archived content\n")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, active)); err != nil {
+		t.Errorf("active file was touched by janitor: %v", err)
+	}
+}
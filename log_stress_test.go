@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentLogging spawns goroutines and subprocesses that all log
+// through the same RotatingWriter/log directory at once, and asserts every
+// line round-trips through ReadLogs intact. It's the regression test for
+// the cross-process file locking and rotation added to guard
+// LogOperation/LogOperationBatch against concurrently running eget
+// processes.
+func TestConcurrentLogging(t *testing.T) {
+	dir := t.TempDir()
+
+	oldWriter, oldLogger := defaultWriter, defaultLogger
+	defaultWriter = NewRotatingWriter(dir, false, 0, 0)
+	defaultLogger = NewLogger(defaultWriter, InfoLevel, TabFormat)
+	t.Cleanup(func() {
+		defaultWriter, defaultLogger = oldWriter, oldLogger
+	})
+
+	const goroutines = 8
+	const subprocesses = 4
+	const perWorker = 25
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	want := make(map[string]bool)
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				action := fmt.Sprintf("goroutine-%d-%d", worker, j)
+				if err := LogOperation("owner/repo", "/tmp/path", action); err != nil {
+					t.Errorf("LogOperation: %v", err)
+					continue
+				}
+				mu.Lock()
+				want[action] = true
+				mu.Unlock()
+			}
+		}(i)
+	}
+
+	for i := 0; i < subprocesses; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=TestHelperLogProcess")
+			cmd.Env = append(os.Environ(),
+				"EGET_HELPER_LOG_PROCESS=1",
+				"EGET_HELPER_LOG_DIR="+dir,
+				"EGET_HELPER_LOG_WORKER="+strconv.Itoa(worker),
+				"EGET_HELPER_LOG_COUNT="+strconv.Itoa(perWorker),
+			)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Errorf("subprocess %d failed: %v\n%s", worker, err, out)
+				return
+			}
+			mu.Lock()
+			for j := 0; j < perWorker; j++ {
+				want[fmt.Sprintf("subprocess-%d-%d", worker, j)] = true
+			}
+			mu.Unlock()
+		}(i)
+	}
+
+	wg.Wait()
+
+	entries, err := ReadLogs()
+	if err != nil {
+		t.Fatalf("ReadLogs: %v", err)
+	}
+
+	got := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		got[e.Action] = true
+	}
+
+	for action := range want {
+		if !got[action] {
+			t.Errorf("missing log line for action %q", action)
+		}
+	}
+	if len(entries) != len(want) {
+		t.Errorf("got %d log entries, want %d (duplicate or corrupted lines)", len(entries), len(want))
+	}
+}
+
+// TestConcurrentSizeRotation drives two RotatingWriter subprocesses sharing
+// a log directory with MaxSizeMB set low enough that each triggers several
+// size-based rotations, and asserts every byte written survives on disk.
+// It's the regression test for the cross-process archive-name collision in
+// rotateForSizeLocked: two processes each keep their own w.seq counter, so
+// without a cross-process-safe claim they can independently produce the
+// identical eget-YYYY-MM-DD.N.log archive name and os.Rename silently
+// clobbers one side's data.
+func TestConcurrentSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	const workers = 2
+	const writesPerWorker = 8
+	const payloadSize = 600 * 1024 // over half of MaxSizeMB below, so about every second write forces a rotation
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var wantBytes int64
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=TestHelperSizeRotationProcess")
+			cmd.Env = append(os.Environ(),
+				"EGET_HELPER_SIZE_ROTATION=1",
+				"EGET_HELPER_LOG_DIR="+dir,
+				"EGET_HELPER_WRITES="+strconv.Itoa(writesPerWorker),
+				"EGET_HELPER_PAYLOAD="+strconv.Itoa(payloadSize),
+				"EGET_HELPER_WORKER="+strconv.Itoa(worker),
+			)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Errorf("subprocess %d failed: %v\n%s", worker, err, out)
+				return
+			}
+			mu.Lock()
+			wantBytes += int64(writesPerWorker * payloadSize)
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	gotBytes, err := logDirSize(dir)
+	if err != nil {
+		t.Fatalf("reading log dir: %v", err)
+	}
+	if gotBytes < wantBytes {
+		t.Errorf("data loss across concurrent size rotation: wrote %d bytes, only %d bytes survive on disk", wantBytes, gotBytes)
+	}
+}
+
+// logDirSize sums the size of every regular file in dir.
+func logDirSize(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return 0, err
+		}
+		total += info.Size()
+	}
+	return total, nil
+}
+
+// TestHelperSizeRotationProcess is not a real test: it's the subprocess
+// entry point TestConcurrentSizeRotation spawns to write a distinct
+// byte-per-worker payload through its own RotatingWriter, repeatedly
+// enough to force several size-based rotations against the shared dir.
+func TestHelperSizeRotationProcess(t *testing.T) {
+	if os.Getenv("EGET_HELPER_SIZE_ROTATION") != "1" {
+		t.Skip("not invoked as a helper process")
+	}
+
+	dir := os.Getenv("EGET_HELPER_LOG_DIR")
+	writes, err := strconv.Atoi(os.Getenv("EGET_HELPER_WRITES"))
+	if err != nil {
+		t.Fatalf("invalid EGET_HELPER_WRITES: %v", err)
+	}
+	payloadSize, err := strconv.Atoi(os.Getenv("EGET_HELPER_PAYLOAD"))
+	if err != nil {
+		t.Fatalf("invalid EGET_HELPER_PAYLOAD: %v", err)
+	}
+	worker, err := strconv.Atoi(os.Getenv("EGET_HELPER_WORKER"))
+	if err != nil {
+		t.Fatalf("invalid EGET_HELPER_WORKER: %v", err)
+	}
+
+	w := NewRotatingWriter(dir, false, 0, 1)
+	payload := make([]byte, payloadSize)
+	for i := range payload {
+		payload[i] = byte('a' + worker)
+	}
+	for i := 0; i < writes; i++ {
+		if _, err := w.Write(payload); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+}
+
+// TestHelperLogProcess is not a real test: it's the subprocess entry point
+// TestConcurrentLogging spawns via exec.Command(os.Args[0], ...), the
+// standard way to exercise behavior that must span real OS processes
+// rather than goroutines. It only does work when EGET_HELPER_LOG_PROCESS
+// is set, so a normal `go test` run treats it as a no-op.
+func TestHelperLogProcess(t *testing.T) {
+	if os.Getenv("EGET_HELPER_LOG_PROCESS") != "1" {
+		t.Skip("not invoked as a helper process")
+	}
+
+	dir := os.Getenv("EGET_HELPER_LOG_DIR")
+	worker := os.Getenv("EGET_HELPER_LOG_WORKER")
+	count, err := strconv.Atoi(os.Getenv("EGET_HELPER_LOG_COUNT"))
+	if err != nil {
+		t.Fatalf("invalid EGET_HELPER_LOG_COUNT: %v", err)
+	}
+
+	defaultWriter = NewRotatingWriter(dir, false, 0, 0)
+	defaultLogger = NewLogger(defaultWriter, InfoLevel, TabFormat)
+
+	for j := 0; j < count; j++ {
+		action := fmt.Sprintf("subprocess-%s-%d", worker, j)
+		if err := LogOperation("owner/repo", "/tmp/path", action); err != nil {
+			t.Fatalf("LogOperation: %v", err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestLogOperationBatchRespectsLevel is the regression test for
+// LogOperationBatch silently bypassing the Logger's level threshold: it
+// used to render every entry unconditionally, so a Debug/Info entry would
+// reach disk even with EGET_LOG_LEVEL/--log-level set to error.
+func TestLogOperationBatchRespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+
+	oldWriter, oldLogger := defaultWriter, defaultLogger
+	defaultLogger = NewLogger(&buf, ErrorLevel, TabFormat)
+	t.Cleanup(func() {
+		defaultWriter, defaultLogger = oldWriter, oldLogger
+	})
+
+	err := LogOperationBatch([]LogEntry{
+		{Level: InfoLevel, Action: "below-threshold"},
+		{Level: ErrorLevel, Action: "at-threshold"},
+	})
+	if err != nil {
+		t.Fatalf("LogOperationBatch: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "below-threshold") {
+		t.Errorf("LogOperationBatch wrote an entry below the Logger's level threshold:\n%s", out)
+	}
+	if !strings.Contains(out, "at-threshold") {
+		t.Errorf("LogOperationBatch dropped an entry at the Logger's level threshold:\n%s", out)
+	}
+}
+
+// TestLogOperationBatchEmptyAfterFilter makes sure filtering every entry
+// out doesn't still touch the underlying writer (e.g. via WriteBatch([])).
+func TestLogOperationBatchEmptyAfterFilter(t *testing.T) {
+	oldLogger := defaultLogger
+	defaultLogger = NewLogger(&countingWriter{}, ErrorLevel, TabFormat)
+	t.Cleanup(func() {
+		defaultLogger = oldLogger
+	})
+
+	if err := LogOperationBatch([]LogEntry{{Level: DebugLevel, Action: "noop"}}); err != nil {
+		t.Fatalf("LogOperationBatch: %v", err)
+	}
+
+	cw := defaultLogger.out.(*countingWriter)
+	if cw.writes != 0 {
+		t.Errorf("LogOperationBatch touched the writer %d times for an all-filtered batch, want 0", cw.writes)
+	}
+}
+
+// countingWriter counts how many times Write/WriteBatch is called, without
+// caring about the content, used to assert on write-count in isolation.
+type countingWriter struct {
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
+
+func (w *countingWriter) WriteBatch(lines []string) error {
+	w.writes++
+	return nil
+}